@@ -0,0 +1,91 @@
+// Copyright 2022 uwu tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package backend abstracts the forge-specific API calls ghcrawl needs to
+// crawl for InnerSource candidates, so that the activity scorer, the
+// innersource.json fetch, and the CLI output can work unchanged whether
+// repos are hosted on GitHub, GitHub Enterprise, or Gitea.
+package backend
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/go-github/v42/github"
+)
+
+// SearchOptions controls pagination for Backend.SearchRepos.
+type SearchOptions struct {
+	Page    int
+	PerPage int
+}
+
+// SearchCriteria describes what Backend.SearchRepos should match. It is
+// expressed structurally, rather than as a pre-built query string, so each
+// backend can translate it into whatever search syntax its forge supports
+// (or reject a criterion it can't express) instead of receiving a query
+// string written in another forge's qualifier syntax.
+type SearchCriteria struct {
+	// Organizations scopes the search to these organizations/owners. A
+	// backend that can't search more than one at a time should error out
+	// rather than silently searching only the first (or none).
+	Organizations []string
+	// Topics scopes the search to repositories tagged with these topics.
+	Topics []string
+	// Visibility is "public", "private", or "" for both.
+	Visibility string
+}
+
+// SearchResult is a single page of repository search results. NextPage is
+// the page to request next, or 0 if there are no more pages.
+type SearchResult struct {
+	Repositories []*github.Repository
+	NextPage     int
+}
+
+// Backend is implemented once per forge. Repositories are always
+// represented with go-github's *github.Repository, since that's already the
+// shape GetRepositoryActivityScore and the JSON output consume; a backend
+// for a non-GitHub forge is responsible for mapping its native API
+// responses onto that type.
+type Backend interface {
+	// SearchRepos returns one page of repositories matching criteria.
+	SearchRepos(ctx context.Context, criteria SearchCriteria, opts SearchOptions) (*SearchResult, error)
+
+	// GetContents returns the decoded content of path on the repository's
+	// default branch. found is false if path does not exist.
+	GetContents(ctx context.Context, owner, repo, path string) (content string, found bool, err error)
+
+	// GetParticipation returns weekly commit counts for roughly the last 52
+	// weeks, oldest week first. A backend with no equivalent stats endpoint
+	// returns (nil, nil); callers treat that as "no participation data".
+	GetParticipation(ctx context.Context, owner, repo string) ([]int, error)
+
+	// GetCommunityProfile reports whether the repository has contribution
+	// guidelines (a CONTRIBUTING.md or equivalent) and, if so, a URL for it.
+	GetCommunityProfile(ctx context.Context, owner, repo string) (hasGuidelines bool, guidelinesURL string, err error)
+}
+
+// MaintainedScorer is an optional capability for backends that can cheaply
+// enumerate recent issue/PR activity, feeding the "maintained" signal in
+// GetRepositoryActivityScore. Callers type-assert for it rather than
+// requiring every Backend to implement it.
+type MaintainedScorer interface {
+	// GetMaintainedScore returns the count of distinct authors of issues,
+	// issue comments, and merged pull requests in the trailing 90 days, along
+	// with the timestamp of the most recent such activity.
+	GetMaintainedScore(ctx context.Context, owner, repo string) (count int, newest time.Time, err error)
+}
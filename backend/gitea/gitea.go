@@ -0,0 +1,213 @@
+// Copyright 2022 uwu tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package gitea implements backend.Backend against a Gitea (or compatible
+// Forgejo/GHE-alike) instance, for self-hosted InnerSource programs that
+// don't run on GitHub.
+package gitea
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	giteasdk "code.gitea.io/sdk/gitea"
+	ghapi "github.com/google/go-github/v42/github"
+	"sigs.k8s.io/release-utils/env"
+
+	"github.com/uwu-tools/go-ghcrawl/backend"
+	ghbackend "github.com/uwu-tools/go-ghcrawl/backend/github"
+)
+
+// TokenEnvKey is the Gitea-specific token environment variable. It is
+// consulted only as a fallback for ghbackend.TokenEnvKey ($GITHUB_TOKEN), so
+// a token already exported for the GitHub backend keeps working unchanged
+// when a user switches --backend=gitea against a self-hosted instance.
+const TokenEnvKey = "GITEA_TOKEN"
+
+// NewClient builds a Gitea API client for baseURL, authenticated via
+// $GITHUB_TOKEN (falling back to $GITEA_TOKEN) if either is set.
+func NewClient(baseURL string) (*giteasdk.Client, error) {
+	token := env.Default(ghbackend.TokenEnvKey, env.Default(TokenEnvKey, ""))
+	return NewClientWithToken(baseURL, token)
+}
+
+// NewClientWithToken builds a Gitea API client for baseURL using token. An
+// empty token results in an unauthenticated client.
+func NewClientWithToken(baseURL, token string) (*giteasdk.Client, error) {
+	var opts []giteasdk.ClientOption
+	if token != "" {
+		opts = append(opts, giteasdk.SetToken(token))
+	}
+
+	return giteasdk.NewClient(baseURL, opts...)
+}
+
+// Backend implements backend.Backend using the Gitea REST API.
+type Backend struct {
+	client  *giteasdk.Client
+	baseURL string
+}
+
+// NewBackend wraps an existing *gitea.Client as a backend.Backend. baseURL
+// is used only to build human-facing guideline URLs.
+func NewBackend(client *giteasdk.Client, baseURL string) *Backend {
+	return &Backend{client: client, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// SearchRepos maps criteria onto Gitea's native search fields rather than
+// passing GitHub qualifier syntax straight through as a raw query (which
+// Gitea would treat as a literal keyword search, silently ignoring org/topic
+// scoping). The Gitea search API supports only a single owner and a single
+// topic per request, so SearchRepos errors out if asked for more than one of
+// either instead of silently scoping to just the first.
+func (b *Backend) SearchRepos(ctx context.Context, criteria backend.SearchCriteria, opts backend.SearchOptions) (*backend.SearchResult, error) {
+	searchOpts := giteasdk.SearchRepoOptions{
+		ListOptions: giteasdk.ListOptions{Page: opts.Page, PageSize: opts.PerPage},
+	}
+
+	switch len(criteria.Organizations) {
+	case 0:
+	case 1:
+		ownerID, err := b.ownerID(ctx, criteria.Organizations[0])
+		if err != nil {
+			return nil, fmt.Errorf("resolving owner %q: %w", criteria.Organizations[0], err)
+		}
+		searchOpts.OwnerID = ownerID
+	default:
+		return nil, fmt.Errorf("gitea backend supports searching at most one organization at a time, got %d", len(criteria.Organizations))
+	}
+
+	switch len(criteria.Topics) {
+	case 0:
+	case 1:
+		searchOpts.Keyword = criteria.Topics[0]
+		searchOpts.KeywordIsTopic = true
+	default:
+		return nil, fmt.Errorf("gitea backend supports searching at most one topic at a time, got %d", len(criteria.Topics))
+	}
+
+	switch criteria.Visibility {
+	case "":
+	case "public":
+		searchOpts.IsPrivate = giteasdk.OptionalBool(false)
+	case "private":
+		searchOpts.IsPrivate = giteasdk.OptionalBool(true)
+	default:
+		return nil, fmt.Errorf("gitea backend does not understand visibility %q", criteria.Visibility)
+	}
+
+	repos, resp, err := b.client.SearchRepos(searchOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	ghRepos := make([]*ghapi.Repository, 0, len(repos))
+	for _, repo := range repos {
+		ghRepos = append(ghRepos, toGitHubRepository(repo))
+	}
+
+	return &backend.SearchResult{Repositories: ghRepos, NextPage: resp.NextPage}, nil
+}
+
+// ownerID resolves an organization or user login to the numeric ID Gitea's
+// search API scopes by, trying organizations first since that's the common
+// case for InnerSource programs.
+func (b *Backend) ownerID(ctx context.Context, login string) (int64, error) {
+	org, _, err := b.client.GetOrg(login)
+	if err == nil {
+		return org.ID, nil
+	}
+
+	user, _, err := b.client.GetUserInfo(login)
+	if err != nil {
+		return 0, err
+	}
+
+	return user.ID, nil
+}
+
+func (b *Backend) GetContents(ctx context.Context, owner, repo, path string) (string, bool, error) {
+	content, resp, err := b.client.GetContents(owner, repo, "", path)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+
+	if content.Content == nil {
+		return "", true, nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(*content.Content)
+	if err != nil {
+		return "", false, fmt.Errorf("decoding %s: %w", path, err)
+	}
+
+	return string(decoded), true, nil
+}
+
+// GetParticipation always returns (nil, nil): the Gitea API has no
+// equivalent of GitHub's weekly commit-participation stats endpoint.
+// Callers treat that as "no participation data available" and skip the
+// associated scoring multiplier.
+func (b *Backend) GetParticipation(ctx context.Context, owner, repo string) ([]int, error) {
+	return nil, nil
+}
+
+// GetCommunityProfile approximates GitHub's community-profile API, which
+// Gitea has no equivalent of, by checking directly for a CONTRIBUTING.md.
+func (b *Backend) GetCommunityProfile(ctx context.Context, owner, repo string) (bool, string, error) {
+	_, found, err := b.GetContents(ctx, owner, repo, "CONTRIBUTING.md")
+	if err != nil || !found {
+		return false, "", err
+	}
+
+	return true, fmt.Sprintf("%s/%s/%s/raw/branch/HEAD/CONTRIBUTING.md", b.baseURL, owner, repo), nil
+}
+
+var _ backend.Backend = (*Backend)(nil)
+
+// toGitHubRepository maps a Gitea repository onto go-github's
+// *github.Repository, the shape the activity scorer and JSON output expect.
+// Only the fields set below are populated; Language is left unset.
+func toGitHubRepository(repo *giteasdk.Repository) *ghapi.Repository {
+	gh := &ghapi.Repository{
+		ID:              ghapi.Int64(repo.ID),
+		Name:            ghapi.String(repo.Name),
+		FullName:        ghapi.String(repo.FullName),
+		HTMLURL:         ghapi.String(repo.HTMLURL),
+		Description:     ghapi.String(repo.Description),
+		ForksCount:      ghapi.Int(repo.Forks),
+		StargazersCount: ghapi.Int(repo.Stars),
+		// WatchersCount mirrors GitHub's own API quirk, where watchers_count
+		// is actually the star count (see ghapi.Repository.WatchersCount).
+		WatchersCount:    ghapi.Int(repo.Stars),
+		SubscribersCount: ghapi.Int(repo.Watchers),
+		OpenIssuesCount:  ghapi.Int(repo.OpenIssues),
+		CreatedAt:        &ghapi.Timestamp{Time: repo.Created},
+		UpdatedAt:        &ghapi.Timestamp{Time: repo.Updated},
+	}
+
+	if repo.Owner != nil {
+		gh.Owner = &ghapi.User{Login: ghapi.String(repo.Owner.UserName)}
+	}
+
+	return gh
+}
@@ -0,0 +1,398 @@
+// Copyright 2022 uwu tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package github implements backend.Backend against the GitHub REST API.
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	ghapi "github.com/google/go-github/v42/github"
+	"golang.org/x/oauth2"
+	"sigs.k8s.io/release-utils/env"
+
+	"github.com/uwu-tools/go-ghcrawl/backend"
+)
+
+// BEGIN COPY FROM sigs.k8s.io/release-sdk/github
+
+const (
+	// TokenEnvKey is the default GitHub token environemt variable key
+	TokenEnvKey = "GITHUB_TOKEN"
+	// GitHubURL Prefix for github URLs
+	GitHubURL = "https://github.com/"
+)
+
+// TODO: we should clean up the functions listed below and agree on the same
+// return type (with or without error):
+// - New
+// - NewClientWithToken
+// - NewEnterpriseClient
+// - NewEnterpriseClientWithToken
+
+// NewClient creates a new default GitHub client. Tokens set via the
+// $GITHUB_TOKEN environment variable will result in an authenticated client.
+// If the $GITHUB_TOKEN is not set, then the client will do unauthenticated
+// GitHub requests.
+func NewClient() *ghapi.Client {
+	// TODO(http): Consider passing a roundtripper here
+	token := env.Default(TokenEnvKey, "")
+	client, _ := NewClientWithToken(token) // nolint: errcheck
+	return client
+}
+
+// NewClientWithToken can be used to specify a GitHub token through parameters.
+// Empty string will result in unauthenticated client, which makes
+// unauthenticated requests.
+func NewClientWithToken(token string) (*ghapi.Client, error) {
+	ctx := context.Background()
+	client := http.DefaultClient
+	state := "unauthenticated"
+	if token != "" {
+		state = strings.TrimPrefix(state, "un")
+		client = oauth2.NewClient(ctx, oauth2.StaticTokenSource(
+			&oauth2.Token{AccessToken: token},
+		))
+	}
+
+	return ghapi.NewClient(withRateLimitTransport(client)), nil
+}
+
+func NewEnterpriseClient(baseURL, uploadURL string) (*ghapi.Client, error) {
+	token := env.Default(TokenEnvKey, "")
+	return NewEnterpriseClientWithToken(baseURL, uploadURL, token)
+}
+
+func NewEnterpriseClientWithToken(baseURL, uploadURL, token string) (*ghapi.Client, error) {
+	ctx := context.Background()
+	client := http.DefaultClient
+	state := "unauthenticated"
+	if token != "" {
+		state = strings.TrimPrefix(state, "un")
+		client = oauth2.NewClient(ctx, oauth2.StaticTokenSource(
+			&oauth2.Token{AccessToken: token},
+		))
+	}
+
+	ghclient, err := ghapi.NewEnterpriseClient(baseURL, uploadURL, withRateLimitTransport(client))
+	if err != nil {
+		return nil, fmt.Errorf("failed to new github client: %s", err)
+	}
+
+	return ghclient, nil
+}
+
+// END COPY FROM sigs.k8s.io/release-sdk/github
+
+// Backend implements backend.Backend using the GitHub REST API.
+type Backend struct {
+	client *ghapi.Client
+}
+
+// NewBackend wraps an existing *github.Client as a backend.Backend.
+func NewBackend(client *ghapi.Client) *Backend {
+	return &Backend{client: client}
+}
+
+func (b *Backend) SearchRepos(ctx context.Context, criteria backend.SearchCriteria, opts backend.SearchOptions) (*backend.SearchResult, error) {
+	query := searchQuery(criteria)
+	searchOpts := &ghapi.SearchOptions{ListOptions: ghapi.ListOptions{Page: opts.Page, PerPage: opts.PerPage}}
+
+	var results *ghapi.RepositoriesSearchResult
+	var resp *ghapi.Response
+	err := retryOnAbuseRateLimit(ctx, func() error {
+		var err error
+		results, resp, err = b.client.Search.Repositories(ctx, query, searchOpts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &backend.SearchResult{Repositories: results.Repositories, NextPage: resp.NextPage}, nil
+}
+
+// searchQuery builds a GitHub search qualifier string from criteria, e.g.
+// "org:a org:b is:public topic:x topic:y". All organizations and topics are
+// included, not just the first of each.
+//
+// The result is deliberately left unescaped: go-github's Search.Repositories
+// URL-encodes the "q" parameter itself, and escaping it here too would
+// double-encode it.
+func searchQuery(criteria backend.SearchCriteria) string {
+	var parts []string
+
+	for _, org := range criteria.Organizations {
+		parts = append(parts, fmt.Sprintf("org:%s", org))
+	}
+
+	if criteria.Visibility != "" {
+		parts = append(parts, fmt.Sprintf("is:%s", criteria.Visibility))
+	}
+
+	for _, topic := range criteria.Topics {
+		parts = append(parts, fmt.Sprintf("topic:%s", topic))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func (b *Backend) GetContents(ctx context.Context, owner, repo, path string) (string, bool, error) {
+	var content *ghapi.RepositoryContent
+	var resp *ghapi.Response
+	err := retryOnAbuseRateLimit(ctx, func() error {
+		var err error
+		content, _, resp, err = b.client.Repositories.GetContents(ctx, owner, repo, path, nil)
+		return err
+	})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+
+	raw, err := content.GetContent()
+	if err != nil {
+		return "", false, err
+	}
+
+	return raw, true, nil
+}
+
+func (b *Backend) GetParticipation(ctx context.Context, owner, repo string) ([]int, error) {
+	return getParticipation(ctx, b.client, owner, repo)
+}
+
+func (b *Backend) GetCommunityProfile(ctx context.Context, owner, repo string) (bool, string, error) {
+	var health *ghapi.CommunityHealthMetrics
+	err := retryOnAbuseRateLimit(ctx, func() error {
+		var err error
+		health, _, err = b.client.Repositories.GetCommunityHealthMetrics(ctx, owner, repo)
+		return err
+	})
+	if err != nil {
+		return false, "", err
+	}
+
+	contributing := health.GetFiles().GetContributing()
+	if contributing == nil {
+		return false, "", nil
+	}
+
+	return true, contributing.GetURL(), nil
+}
+
+// GetMaintainedScore implements backend.MaintainedScorer.
+func (b *Backend) GetMaintainedScore(ctx context.Context, owner, repo string) (int, time.Time, error) {
+	return getMaintainedScore(ctx, b.client, owner, repo)
+}
+
+var _ backend.Backend = (*Backend)(nil)
+var _ backend.MaintainedScorer = (*Backend)(nil)
+
+// secondaryRateLimitRetryDelay is the fallback back-off used when GitHub
+// signals a secondary (abuse-detection) rate limit without a Retry-After
+// value.
+const secondaryRateLimitRetryDelay = 30 * time.Second
+
+// retryOnAbuseRateLimit calls call, retrying once after GitHub's suggested
+// back-off if it was rejected due to a secondary rate limit (go-github
+// surfaces this as a typed *github.AbuseRateLimitError, distinct from the
+// primary rate limit that rateLimitTransport already waits out
+// transparently). Every call this backend makes against the GitHub API goes
+// through this, since any endpoint can be abuse-rate-limited, not just
+// search.
+func retryOnAbuseRateLimit(ctx context.Context, call func() error) error {
+	err := call()
+
+	var abuseErr *ghapi.AbuseRateLimitError
+	if !errors.As(err, &abuseErr) {
+		return err
+	}
+
+	wait := secondaryRateLimitRetryDelay
+	if abuseErr.RetryAfter != nil {
+		wait = *abuseErr.RetryAfter
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(wait):
+	}
+
+	return call()
+}
+
+// maintainedWindow is the trailing window considered for the "maintained"
+// signal, borrowed from the OSSF Scorecard Maintained check:
+// https://github.com/ossf/scorecard/blob/main/docs/checks.md#maintained
+const maintainedWindow = 90 * 24 * time.Hour
+
+// getMaintainedScore counts the distinct authors of issues and issue comments
+// updated, and of pull requests merged, in the trailing maintainedWindow, along
+// with the timestamp of the most recent such activity. A repository with few
+// stars but active triage still surfaces as "maintained".
+func getMaintainedScore(ctx context.Context, gh *ghapi.Client, owner, name string) (count int, newest time.Time, err error) {
+	since := time.Now().Add(-maintainedWindow)
+	participants := map[string]struct{}{}
+
+	issueOpts := &ghapi.IssueListByRepoOptions{
+		State:       "all",
+		Since:       since,
+		ListOptions: ghapi.ListOptions{PerPage: 100},
+	}
+	for {
+		var issues []*ghapi.Issue
+		var resp *ghapi.Response
+		err := retryOnAbuseRateLimit(ctx, func() error {
+			var err error
+			issues, resp, err = gh.Issues.ListByRepo(ctx, owner, name, issueOpts)
+			return err
+		})
+		if err != nil {
+			return 0, time.Time{}, err
+		}
+
+		for _, issue := range issues {
+			// The issues endpoint also lists pull requests; only merged ones
+			// count, and those are handled below.
+			if issue.IsPullRequest() {
+				continue
+			}
+
+			participants[issue.GetUser().GetLogin()] = struct{}{}
+			if updatedAt := issue.GetUpdatedAt(); updatedAt.After(newest) {
+				newest = updatedAt
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		issueOpts.Page = resp.NextPage
+	}
+
+	commentOpts := &ghapi.IssueListCommentsOptions{
+		Since:       &since,
+		ListOptions: ghapi.ListOptions{PerPage: 100},
+	}
+	for {
+		var comments []*ghapi.IssueComment
+		var resp *ghapi.Response
+		// An issue number of 0 returns comments across all issues in the repo.
+		err := retryOnAbuseRateLimit(ctx, func() error {
+			var err error
+			comments, resp, err = gh.Issues.ListComments(ctx, owner, name, 0, commentOpts)
+			return err
+		})
+		if err != nil {
+			return 0, time.Time{}, err
+		}
+
+		for _, comment := range comments {
+			participants[comment.GetUser().GetLogin()] = struct{}{}
+			if updatedAt := comment.GetUpdatedAt(); updatedAt.After(newest) {
+				newest = updatedAt
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		commentOpts.Page = resp.NextPage
+	}
+
+	prOpts := &ghapi.PullRequestListOptions{
+		State:       "closed",
+		ListOptions: ghapi.ListOptions{PerPage: 100},
+	}
+	for {
+		var prs []*ghapi.PullRequest
+		var resp *ghapi.Response
+		err := retryOnAbuseRateLimit(ctx, func() error {
+			var err error
+			prs, resp, err = gh.PullRequests.List(ctx, owner, name, prOpts)
+			return err
+		})
+		if err != nil {
+			return 0, time.Time{}, err
+		}
+
+		for _, pr := range prs {
+			mergedAt := pr.GetMergedAt()
+			if mergedAt.IsZero() || mergedAt.Before(since) {
+				continue
+			}
+
+			participants[pr.GetUser().GetLogin()] = struct{}{}
+			if mergedAt.After(newest) {
+				newest = mergedAt
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		prOpts.Page = resp.NextPage
+	}
+
+	return len(participants), newest, nil
+}
+
+// participationMaxAttempts and participationRetryDelay bound how long
+// getParticipation will wait for GitHub to finish computing commit stats.
+const (
+	participationMaxAttempts = 5
+	participationRetryDelay  = 2 * time.Second
+)
+
+// getParticipation fetches the weekly commit counts for a repository,
+// retrying while GitHub is still computing the statistics. GitHub signals
+// "still computing" by returning a 202 Accepted response, surfaced by
+// go-github as a *github.AcceptedError.
+func getParticipation(ctx context.Context, gh *ghapi.Client, owner, name string) ([]int, error) {
+	for attempt := 0; attempt < participationMaxAttempts; attempt++ {
+		var participation *ghapi.RepositoryParticipation
+		err := retryOnAbuseRateLimit(ctx, func() error {
+			var err error
+			participation, _, err = gh.Repositories.ListParticipation(ctx, owner, name)
+			return err
+		})
+		if err == nil {
+			return participation.All, nil
+		}
+
+		var acceptedErr *ghapi.AcceptedError
+		if !errors.As(err, &acceptedErr) {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(participationRetryDelay):
+		}
+	}
+
+	return nil, fmt.Errorf("timed out waiting for %s/%s commit stats to be computed", owner, name)
+}
@@ -0,0 +1,80 @@
+// Copyright 2022 uwu tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package github
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// rateLimitTransport wraps an http.RoundTripper and transparently waits out
+// GitHub's primary rate limit instead of returning an error, by inspecting
+// the X-RateLimit-Remaining / X-RateLimit-Reset response headers documented
+// at https://docs.github.com/en/rest/overview/rate-limits-for-the-rest-api
+//
+// It deliberately leaves secondary (abuse-detection) rate limits alone: those
+// come back as a 403 with a non-zero X-RateLimit-Remaining, and go-github
+// already turns them into a typed *github.AbuseRateLimitError for callers to
+// retry explicitly (see retryOnAbuseRateLimit).
+type rateLimitTransport struct {
+	next http.RoundTripper
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	resp, err := next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusForbidden {
+		return resp, err
+	}
+
+	remaining, convErr := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if convErr != nil || remaining > 0 {
+		return resp, err
+	}
+
+	resetUnix, convErr := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if convErr != nil {
+		return resp, err
+	}
+
+	wait := time.Until(time.Unix(resetUnix, 0))
+	if wait <= 0 {
+		return resp, err
+	}
+
+	select {
+	case <-req.Context().Done():
+		return resp, req.Context().Err()
+	case <-time.After(wait):
+	}
+
+	return next.RoundTrip(req.Clone(req.Context()))
+}
+
+// withRateLimitTransport returns a shallow copy of client with its transport
+// wrapped by rateLimitTransport, so API calls transparently wait out GitHub's
+// primary rate limit instead of erroring.
+func withRateLimitTransport(client *http.Client) *http.Client {
+	wrapped := *client
+	wrapped.Transport = &rateLimitTransport{next: client.Transport}
+	return &wrapped
+}
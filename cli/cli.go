@@ -21,16 +21,23 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
-	"net/http"
-	"net/url"
 	"os"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/go-github/v42/github"
 	"github.com/spf13/cobra"
-	"golang.org/x/oauth2"
-	"sigs.k8s.io/release-utils/env"
+
+	"github.com/uwu-tools/go-ghcrawl/backend"
+	giteabackend "github.com/uwu-tools/go-ghcrawl/backend/gitea"
+	ghbackend "github.com/uwu-tools/go-ghcrawl/backend/github"
+	"github.com/uwu-tools/go-ghcrawl/output"
+)
+
+// BackendGitHub and BackendGitea are the supported values for --backend.
+const (
+	BackendGitHub = "github"
+	BackendGitea  = "gitea"
 )
 
 type Options struct {
@@ -38,10 +45,15 @@ type Options struct {
 	Organizations []string
 	Topics        []string
 	Visibility    string
+	Backend       string
+	BaseURL       string
+	Output        string
 }
 
 var opts = &Options{
 	Visibility: "public",
+	Backend:    BackendGitHub,
+	Output:     string(output.FormatPortal),
 }
 
 var rootCmd = &cobra.Command{
@@ -86,6 +98,57 @@ func init() {
 		[]string{},
 		"topics to query",
 	)
+
+	rootCmd.PersistentFlags().StringVar(
+		&opts.Backend,
+		"backend",
+		BackendGitHub,
+		fmt.Sprintf("forge backend to crawl (%s, %s)", BackendGitHub, BackendGitea),
+	)
+
+	rootCmd.PersistentFlags().StringVar(
+		&opts.BaseURL,
+		"base-url",
+		"",
+		"base URL of the forge instance (required for --backend=gitea)",
+	)
+
+	rootCmd.PersistentFlags().StringVar(
+		&opts.Output,
+		"output",
+		string(output.FormatPortal),
+		fmt.Sprintf("output format (%s, %s, %s)", output.FormatRaw, output.FormatPortal, output.FormatCSV),
+	)
+}
+
+// newBackend constructs the backend.Backend selected by opts.Backend.
+func newBackend() (backend.Backend, error) {
+	switch opts.Backend {
+	case BackendGitHub:
+		if opts.BaseURL != "" {
+			client, err := ghbackend.NewEnterpriseClient(opts.BaseURL, opts.BaseURL)
+			if err != nil {
+				return nil, fmt.Errorf("creating github enterprise client: %w", err)
+			}
+
+			return ghbackend.NewBackend(client), nil
+		}
+
+		return ghbackend.NewBackend(ghbackend.NewClient()), nil
+	case BackendGitea:
+		if opts.BaseURL == "" {
+			return nil, fmt.Errorf("--base-url is required for --backend=%s", BackendGitea)
+		}
+
+		client, err := giteabackend.NewClient(opts.BaseURL)
+		if err != nil {
+			return nil, fmt.Errorf("creating gitea client: %w", err)
+		}
+
+		return giteabackend.NewBackend(client, opts.BaseURL), nil
+	default:
+		return nil, fmt.Errorf("unknown backend %q (expected %s or %s)", opts.Backend, BackendGitHub, BackendGitea)
+	}
 }
 
 func run(cmd *cobra.Command, args []string) {
@@ -95,17 +158,70 @@ func run(cmd *cobra.Command, args []string) {
 		panic(err)
 	}
 
-	output, err := json.Marshal(repos)
+	format := output.Format(opts.Output)
+
+	var encoded []byte
+	if format == output.FormatRaw {
+		encoded, err = json.Marshal(repos)
+	} else {
+		encoded, err = output.Marshal(format, toOutputRepos(repos))
+	}
 	if err != nil {
 		panic(err)
 	}
 
-	fmt.Println(string(output))
+	fmt.Println(string(encoded))
+}
+
+// toOutputRepos converts repos to the output package's portal schema.
+func toOutputRepos(repos []*Repo) []output.Repo {
+	out := make([]output.Repo, 0, len(repos))
+	for _, repo := range repos {
+		out = append(out, toOutputRepo(repo))
+	}
+
+	return out
+}
+
+// toOutputRepo converts a single repo to the output package's portal schema.
+func toOutputRepo(repo *Repo) output.Repo {
+	ghRepo := repo.GH
+
+	return output.Repo{
+		Name:            ghRepo.GetName(),
+		FullName:        ghRepo.GetFullName(),
+		HTMLURL:         ghRepo.GetHTMLURL(),
+		Description:     ghRepo.GetDescription(),
+		Owner:           ghRepo.GetOwner().GetLogin(),
+		Language:        ghRepo.GetLanguage(),
+		ForksCount:      ghRepo.GetForksCount(),
+		StargazersCount: ghRepo.GetStargazersCount(),
+		WatchersCount:   ghRepo.GetWatchersCount(),
+		OpenIssuesCount: ghRepo.GetOpenIssuesCount(),
+		CreatedAt:       ghRepo.GetCreatedAt().Time,
+		UpdatedAt:       ghRepo.GetUpdatedAt().Time,
+		InnerSourceMetadata: &output.InnerSourceMetadata{
+			Title:         repo.Metadata.Title,
+			Motivation:    repo.Metadata.Motivation,
+			Contributions: repo.Metadata.Contributions,
+			Skills:        repo.Metadata.Skills,
+			Logo:          repo.Metadata.Logo,
+			Docs:          repo.Metadata.Docs,
+			Participation: repo.Metadata.Participation,
+			Guidelines:    repo.Metadata.Guidelines,
+			Score:         repo.Metadata.Score,
+		},
+	}
 }
 
 // TODO: Reorganize below into separate packages
 
-func GetRepos(ctx context.Context) ([]*github.Repository, error) {
+// innerSourceMetadataPath is the well-known location of a repository's
+// InnerSource metadata file, as documented at
+// https://github.com/SAP/project-portal-for-innersource/blob/main/docs/LISTING.md
+const innerSourceMetadataPath = "innersource.json"
+
+func GetRepos(ctx context.Context) ([]*Repo, error) {
 	/*
 		Crawler reference implementations
 
@@ -124,127 +240,183 @@ func GetRepos(ctx context.Context) ([]*github.Repository, error) {
 		* [AWS CodeCommit](https://aws.amazon.com/codecommit/) Crawler implementation with Python: [aws-samples/codecommit-crawler-innersource](https://github.com/aws-samples/codecommit-crawler-innersource)
 	*/
 
-	gh := NewClient()
+	be, err := newBackend()
+	if err != nil {
+		return nil, err
+	}
 
-	// TODO: Populate search options
-	query := getSearchQuery(opts)
-	results, _, err := gh.Search.Repositories(
-		ctx,
-		query,
-		&github.SearchOptions{},
-	)
+	ghRepos, err := searchRepos(ctx, be)
 	if err != nil {
 		return nil, err
 	}
 
-	repos := results.Repositories
+	repos := make([]*Repo, 0, len(ghRepos))
+	for _, ghRepo := range ghRepos {
+		// A single repo with bad metadata (a malformed innersource.json, a
+		// stats endpoint that never finishes computing, ...) shouldn't abort
+		// a scan across many repos, so failures here are logged and skipped
+		// rather than returned.
+		metadata, err := getInnerSourceMetadata(ctx, be, ghRepo)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "skipping %s: %v\n", ghRepo.GetFullName(), err)
+			continue
+		}
+
+		repo := &Repo{
+			GH:       ghRepo,
+			Metadata: metadata,
+		}
+		GetRepositoryActivityScore(repo)
+
+		repos = append(repos, repo)
+	}
 
 	return repos, nil
 }
 
-func getSearchQuery(opts *Options) string {
-	var query string
+// maxConcurrentOrgSearches bounds how many organization searches run at
+// once, so scanning a large --orgs list doesn't hammer the search API or
+// exhaust the rate limit in one burst.
+const maxConcurrentOrgSearches = 5
+
+// searchRepos runs the configured search across every organization in
+// opts.Organizations concurrently (or once, unscoped to any organization, if
+// none were given), paginating each search to completion and deduping
+// results by repository ID.
+func searchRepos(ctx context.Context, be backend.Backend) ([]*github.Repository, error) {
+	orgs := opts.Organizations
+	if len(orgs) == 0 {
+		orgs = []string{""}
+	}
+
+	type orgResult struct {
+		repos []*github.Repository
+		err   error
+	}
+
+	resultsCh := make(chan orgResult, len(orgs))
+	sem := make(chan struct{}, maxConcurrentOrgSearches)
+
+	var wg sync.WaitGroup
+	for _, org := range orgs {
+		org := org
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
 
-	// TODO: Can we handle multiple orgs in this query?
-	var orgs string
-	var orgsPart string
+			sem <- struct{}{}
+			defer func() { <-sem }()
 
-	if len(opts.Organizations) > 0 {
-		orgs = opts.Organizations[0]
-		orgsPart = fmt.Sprintf("org:%s", orgs)
+			repos, err := searchOrgRepos(ctx, be, org)
+			resultsCh <- orgResult{repos: repos, err: err}
+		}()
 	}
 
-	visibilityPart := fmt.Sprintf("is:%s", opts.Visibility)
+	wg.Wait()
+	close(resultsCh)
 
-	var topics string
-	var topicsPart string
+	seen := make(map[int64]struct{})
+	var repos []*github.Repository
+	for res := range resultsCh {
+		if res.err != nil {
+			return nil, res.err
+		}
+
+		for _, repo := range res.repos {
+			id := repo.GetID()
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
 
-	// TODO: Can we handle multiple topics in this query?
-	if len(opts.Topics) > 0 {
-		topics = opts.Topics[0]
-		topicsPart = fmt.Sprintf("topic:%s", topics)
+			repos = append(repos, repo)
+		}
 	}
 
-	query += orgsPart
-	if visibilityPart != "" {
-		query += "+" + visibilityPart
+	return repos, nil
+}
+
+// searchOrgRepos runs the configured search scoped to a single organization
+// (or unscoped, if org is empty), following pagination until the backend
+// reports no further pages.
+func searchOrgRepos(ctx context.Context, be backend.Backend, org string) ([]*github.Repository, error) {
+	criteria := backend.SearchCriteria{
+		Topics:     opts.Topics,
+		Visibility: opts.Visibility,
 	}
-	if topicsPart != "" {
-		query += "+" + topicsPart
+	if org != "" {
+		criteria.Organizations = []string{org}
 	}
 
-	return url.QueryEscape(query)
-}
+	var repos []*github.Repository
+	searchOpts := backend.SearchOptions{PerPage: 100}
 
-// BEGIN COPY FROM sigs.k8s.io/release-sdk/github
+	for {
+		result, err := be.SearchRepos(ctx, criteria, searchOpts)
+		if err != nil {
+			return nil, err
+		}
 
-const (
-	// TokenEnvKey is the default GitHub token environemt variable key
-	TokenEnvKey = "GITHUB_TOKEN"
-	// GitHubURL Prefix for github URLs
-	GitHubURL = "https://github.com/"
-)
+		repos = append(repos, result.Repositories...)
+
+		if result.NextPage == 0 {
+			break
+		}
+		searchOpts.Page = result.NextPage
+	}
 
-// TODO: we should clean up the functions listed below and agree on the same
-// return type (with or without error):
-// - New
-// - NewClientWithToken
-// - NewEnterpriseClient
-// - NewEnterpriseClientWithToken
-
-// New creates a new default GitHub client. Tokens set via the $GITHUB_TOKEN
-// environment variable will result in an authenticated client.
-// If the $GITHUB_TOKEN is not set, then the client will do unauthenticated
-// GitHub requests.
-func NewClient() *github.Client {
-	// TODO(http): Consider passing a roundtripper here
-	token := env.Default(TokenEnvKey, "")
-	client, _ := NewClientWithToken(token) // nolint: errcheck
-	return client
+	return repos, nil
 }
 
-// NewClientWithToken can be used to specify a GitHub token through parameters.
-// Empty string will result in unauthenticated client, which makes
-// unauthenticated requests.
-func NewClientWithToken(token string) (*github.Client, error) {
-	ctx := context.Background()
-	client := http.DefaultClient
-	state := "unauthenticated"
-	if token != "" {
-		state = strings.TrimPrefix(state, "un")
-		client = oauth2.NewClient(ctx, oauth2.StaticTokenSource(
-			&oauth2.Token{AccessToken: token},
-		))
+// getInnerSourceMetadata downloads and merges the innersource.json metadata
+// file for a single repository (if one exists) and checks whether the
+// repository advertises contribution guidelines via the community-profile
+// API, so that the +100 guideline boost in GetRepositoryActivityScore can
+// fire.
+func getInnerSourceMetadata(ctx context.Context, be backend.Backend, ghRepo *github.Repository) (*InnerSourceMetadata, error) {
+	metadata := &InnerSourceMetadata{}
+
+	owner := ghRepo.GetOwner().GetLogin()
+	name := ghRepo.GetName()
+
+	raw, found, err := be.GetContents(ctx, owner, name, innerSourceMetadataPath)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s for %s/%s: %w", innerSourceMetadataPath, owner, name, err)
 	}
 
-	return github.NewClient(client), nil
-}
+	if found {
+		if err := json.Unmarshal([]byte(raw), metadata); err != nil {
+			return nil, fmt.Errorf("unmarshalling %s for %s/%s: %w", innerSourceMetadataPath, owner, name, err)
+		}
+	}
 
-func NewEnterpriseClient(baseURL, uploadURL string) (*github.Client, error) {
-	token := env.Default(TokenEnvKey, "")
-	return NewEnterpriseClientWithToken(baseURL, uploadURL, token)
-}
+	hasGuidelines, guidelinesURL, err := be.GetCommunityProfile(ctx, owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("fetching community profile for %s/%s: %w", owner, name, err)
+	}
 
-func NewEnterpriseClientWithToken(baseURL, uploadURL, token string) (*github.Client, error) {
-	ctx := context.Background()
-	client := http.DefaultClient
-	state := "unauthenticated"
-	if token != "" {
-		state = strings.TrimPrefix(state, "un")
-		client = oauth2.NewClient(ctx, oauth2.StaticTokenSource(
-			&oauth2.Token{AccessToken: token},
-		))
+	if hasGuidelines {
+		metadata.Guidelines = guidelinesURL
 	}
 
-	ghclient, err := github.NewEnterpriseClient(baseURL, uploadURL, client)
+	participation, err := be.GetParticipation(ctx, owner, name)
 	if err != nil {
-		return nil, fmt.Errorf("failed to new github client: %s", err)
+		return nil, fmt.Errorf("fetching participation stats for %s/%s: %w", owner, name, err)
 	}
+	metadata.Participation = participation
 
-	return ghclient, nil
-}
+	if scorer, ok := be.(backend.MaintainedScorer); ok {
+		maintainedScore, maintainedAt, err := scorer.GetMaintainedScore(ctx, owner, name)
+		if err != nil {
+			return nil, fmt.Errorf("fetching maintained signal for %s/%s: %w", owner, name, err)
+		}
+		metadata.MaintainedScore = maintainedScore
+		metadata.MaintainedAt = maintainedAt
+	}
 
-// END COPY FROM sigs.k8s.io/release-sdk/github
+	return metadata, nil
+}
 
 type Repo struct {
 	GH       *github.Repository
@@ -259,18 +431,21 @@ type Repo struct {
 func GetRepositoryActivityScore(repo *Repo) int {
 	ghRepo := repo.GH
 
-	// TODO: Consider handling score as a float64
 	// initial score is 50 to give active repos with low GitHub KPIs (forks,
 	// watchers, stars) a better starting point
-	score := 50
+	//
+	// NOTE: score is kept as a float64 throughout so that the fractional
+	// multipliers below don't get truncated to whole numbers before the final
+	// math.Round.
+	score := float64(50)
 
 	// weighting: forks and watches count most, then stars, add some little score
 	// for open issues, too
 	// TODO: Does it matter if these values are not populated?
-	score += *ghRepo.ForksCount * 5
-	score += *ghRepo.SubscribersCount
-	score += *ghRepo.StargazersCount / 3
-	score += *ghRepo.OpenIssuesCount / 5
+	score += float64(ghRepo.GetForksCount() * 5)
+	score += float64(ghRepo.GetSubscribersCount())
+	score += float64(ghRepo.GetStargazersCount() / 3)
+	score += float64(ghRepo.GetOpenIssuesCount() / 5)
 
 	// updated in last 3 months: adds a bonus multiplier between 0..1 to overall
 	// score (1 = updated today, 0 = updated more than 100 days ago)
@@ -280,26 +455,21 @@ func GetRepositoryActivityScore(repo *Repo) int {
 	// TODO: Is this an accurate representation of days?
 	daysSinceLastUpdate := timeSinceLastUpdate.Hours() / 24
 
-	updateMultiplier64 := (1 + (100 - math.Min(daysSinceLastUpdate, 100))) / 100
-	updateMultiplier := int(updateMultiplier64)
-	score *= int(updateMultiplier)
+	updateMultiplier := (1 + (100 - math.Min(daysSinceLastUpdate, 100))) / 100
+	score *= updateMultiplier
 
-	// evaluate participation stats for the previous 3 months
-	// TODO: Populate logic
-	/*
-		repo._InnerSourceMetadata = repo._InnerSourceMetadata || {};
-		if (repo._InnerSourceMetadata.participation) {
-				// average commits: adds a bonus multiplier between 0..1 to overall score (1 = >10 commits per week, 0 = less than 3 commits per week)
-				let iAverageCommitsPerWeek = repo._InnerSourceMetadata.participation.slice(repo._InnerSourceMetadata.participation.length - 13).reduce((a, b) => a + b) / 13;
-				iScore = iScore * ((1 + (Math.min(Math.max(iAverageCommitsPerWeek - 3, 0), 7))) / 7);
-		}
-	*/
+	// evaluate participation stats for the previous 3 months: multiplies the
+	// overall score by a factor between 1/7 (3 or fewer commits per week) and
+	// 8/7 (10 or more commits per week)
+	if participation := repo.Metadata.Participation; len(participation) > 0 {
+		avgCommitsPerWeek := averageWeeklyCommits(participation, 13)
+		score *= (1 + clamp(avgCommitsPerWeek-3, 0, 7)) / 7
+	}
 
 	// boost calculation:
 	// all repositories updated in the previous year will receive a boost of
 	// maximum 1000 declining by days since last update
-	boost64 := (1000 - math.Min(daysSinceLastUpdate, 365)*2.74)
-	boost := int(boost64)
+	boost := 1000 - math.Min(daysSinceLastUpdate, 365)*2.74
 
 	// gradually scale down boost according to repository creation date to mix
 	// with "real" engagement stats
@@ -309,41 +479,78 @@ func GetRepositoryActivityScore(repo *Repo) int {
 	// TODO: Is this an accurate representation of days?
 	daysSinceCreation := timeSinceCreation.Hours() / 24
 
-	creationBoost64 := (365 - math.Min(daysSinceCreation, 365)) / 365
-	creationBoost := int(creationBoost64)
+	creationBoost := (365 - math.Min(daysSinceCreation, 365)) / 365
 	boost *= creationBoost
 
 	// add boost to score
 	score += boost
 
 	// give projects with a meaningful description a static boost of 50
-	if len(*ghRepo.Description) > 30 || len(repo.Metadata.Motivation) > 30 {
+	if len(ghRepo.GetDescription()) > 30 || len(repo.Metadata.Motivation) > 30 {
 		score += 50
 	}
 
 	// give projects with contribution guidelines (CONTRIBUTING.md) file a static
 	// boost of 100
-	// TODO: Add logic for querying CONTRIBUTING.md URL from GitHub
 	if repo.Metadata.Guidelines != "" {
 		score += 100
 	}
 
+	// reward active triage even on repos with few stars: a bonus proportional
+	// to log(unique active participants+1) over the trailing 90 days, capped
+	// so it can't dominate the score.
+	if repo.Metadata.MaintainedScore > 0 {
+		maintainedBonus := math.Log(float64(repo.Metadata.MaintainedScore+1)) * 50
+		score += math.Min(maintainedBonus, maintainedBonusCap)
+	}
+
 	// build in a logarithmic scale for very active projects (open ended but
 	// stabilizing around 5000)
 	if score > 3000 {
-		logScore64 := 3000 + math.Log(float64(score))*100
-		logScore := int(logScore64)
-		score = logScore
+		score = 3000 + math.Log(score)*100
 	}
 
 	// final score is a rounded value starting from 0 (subtract the initial
 	// value)
-	score = int(math.Round(float64(score) - 50))
+	finalScore := int(math.Round(score - 50))
 
 	// add score to metadata on the fly
-	repo.Metadata.Score = score
+	repo.Metadata.Score = finalScore
+
+	return finalScore
+}
 
-	return score
+// averageWeeklyCommits returns the average commit count over the trailing
+// weeks weeks of a 52-week participation series, as returned by
+// RepositoriesService.ListParticipation.
+func averageWeeklyCommits(participation []int, weeks int) float64 {
+	if weeks > len(participation) {
+		weeks = len(participation)
+	}
+
+	trailing := participation[len(participation)-weeks:]
+
+	var sum int
+	for _, commits := range trailing {
+		sum += commits
+	}
+
+	return float64(sum) / float64(weeks)
+}
+
+// maintainedBonusCap bounds the "maintained" signal bonus applied in
+// GetRepositoryActivityScore.
+const maintainedBonusCap = 200
+
+// clamp restricts v to the inclusive range [min, max].
+func clamp(v, min, max float64) float64 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
 }
 
 // innersource.json
@@ -383,16 +590,25 @@ type InnerSourceMetadata struct {
 		}
 	*/
 
-	Title         string
-	Motivation    string
-	Contributions []string
-	Skills        []string
-	Logo          string
-	Docs          string
-	Language      string
+	Title         string   `json:"title,omitempty"`
+	Motivation    string   `json:"motivation,omitempty"`
+	Contributions []string `json:"contributions,omitempty"`
+	Skills        []string `json:"skills,omitempty"`
+	Logo          string   `json:"logo,omitempty"`
+	Docs          string   `json:"docs,omitempty"`
+	Language      string   `json:"language,omitempty"`
 
 	// TODO: These fields are not documented but potentially in use
-	Participation string
+
+	// Participation holds the weekly commit counts for the last 52 weeks, as
+	// returned by RepositoriesService.ListParticipation, oldest week first.
+	Participation []int
 	Guidelines    string
 	Score         int
+
+	// MaintainedScore is the count of distinct authors of issues, issue
+	// comments, and merged pull requests in the trailing 90 days. MaintainedAt
+	// is the timestamp of the most recent such activity.
+	MaintainedScore int
+	MaintainedAt    time.Time
 }
@@ -0,0 +1,141 @@
+// Copyright 2022 uwu tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package output encodes crawled repositories as the SAP project-portal
+// `repos.json` schema documented at
+// https://github.com/SAP/project-portal-for-innersource/blob/main/docs/LISTING.md
+// so ghcrawl is a drop-in replacement for the Ruby/Python reference
+// crawlers that feed that portal.
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Format selects how Marshal encodes repositories.
+type Format string
+
+// FormatRaw, FormatPortal, and FormatCSV are the supported --output values.
+// FormatRaw is handled upstream of this package: it prints the crawler's own
+// []*cli.Repo shape unchanged, for callers that don't want the portal schema.
+const (
+	FormatRaw    Format = "raw"
+	FormatPortal Format = "portal"
+	FormatCSV    Format = "csv"
+)
+
+// InnerSourceMetadata is the portal's `_InnerSourceMetadata` object, as
+// documented at
+// https://github.com/SAP/project-portal-for-innersource/blob/main/docs/LISTING.md#syntax-definition-of-innersourcejson
+type InnerSourceMetadata struct {
+	Title         string   `json:"title,omitempty"`
+	Motivation    string   `json:"motivation,omitempty"`
+	Contributions []string `json:"contributions,omitempty"`
+	Skills        []string `json:"skills,omitempty"`
+	Logo          string   `json:"logo,omitempty"`
+	Docs          string   `json:"docs,omitempty"`
+	Participation []int    `json:"participation,omitempty"`
+	Guidelines    string   `json:"guidelines,omitempty"`
+	Score         int      `json:"score"`
+}
+
+// Repo is a single `repos.json` entry: the subset of GitHub's repository
+// fields the portal reads, plus the merged InnerSource metadata.
+type Repo struct {
+	Name                string               `json:"name"`
+	FullName            string               `json:"full_name"`
+	HTMLURL             string               `json:"html_url"`
+	Description         string               `json:"description"`
+	Owner               string               `json:"owner"`
+	Language            string               `json:"language"`
+	ForksCount          int                  `json:"forks_count"`
+	StargazersCount     int                  `json:"stargazers_count"`
+	WatchersCount       int                  `json:"watchers_count"`
+	OpenIssuesCount     int                  `json:"open_issues_count"`
+	CreatedAt           time.Time            `json:"created_at"`
+	UpdatedAt           time.Time            `json:"updated_at"`
+	InnerSourceMetadata *InnerSourceMetadata `json:"_InnerSourceMetadata,omitempty"`
+}
+
+// csvHeader lists the columns written by Marshal(FormatCSV, ...), in order.
+// The nested InnerSourceMetadata is flattened to its score, since the rest
+// of its fields (contributions, skills, ...) don't fit a flat row.
+var csvHeader = []string{
+	"name", "full_name", "html_url", "description", "owner", "language",
+	"forks_count", "stargazers_count", "watchers_count", "open_issues_count",
+	"created_at", "updated_at", "score",
+}
+
+// Marshal encodes repos per format. FormatRaw is not accepted here: callers
+// handle it upstream by marshaling their own repo type directly.
+func Marshal(format Format, repos []Repo) ([]byte, error) {
+	switch format {
+	case FormatPortal:
+		return json.MarshalIndent(repos, "", "  ")
+	case FormatCSV:
+		return marshalCSV(repos)
+	default:
+		return nil, fmt.Errorf("unsupported output format %q (expected %s or %s)", format, FormatPortal, FormatCSV)
+	}
+}
+
+func marshalCSV(repos []Repo) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w := csv.NewWriter(&buf)
+	if err := w.Write(csvHeader); err != nil {
+		return nil, err
+	}
+
+	for _, repo := range repos {
+		var score string
+		if repo.InnerSourceMetadata != nil {
+			score = strconv.Itoa(repo.InnerSourceMetadata.Score)
+		}
+
+		record := []string{
+			repo.Name,
+			repo.FullName,
+			repo.HTMLURL,
+			repo.Description,
+			repo.Owner,
+			repo.Language,
+			strconv.Itoa(repo.ForksCount),
+			strconv.Itoa(repo.StargazersCount),
+			strconv.Itoa(repo.WatchersCount),
+			strconv.Itoa(repo.OpenIssuesCount),
+			repo.CreatedAt.Format(time.RFC3339),
+			repo.UpdatedAt.Format(time.RFC3339),
+			score,
+		}
+
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
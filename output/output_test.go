@@ -0,0 +1,115 @@
+// Copyright 2022 uwu tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package output
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata/")
+
+func testRepos() []Repo {
+	created := time.Date(2020, time.January, 2, 3, 4, 5, 0, time.UTC)
+	updated := time.Date(2022, time.June, 7, 8, 9, 10, 0, time.UTC)
+
+	return []Repo{
+		{
+			Name:            "widget",
+			FullName:        "acme/widget",
+			HTMLURL:         "https://github.com/acme/widget",
+			Description:     "A widget.",
+			Owner:           "acme",
+			Language:        "Go",
+			ForksCount:      3,
+			StargazersCount: 42,
+			WatchersCount:   42,
+			OpenIssuesCount: 5,
+			CreatedAt:       created,
+			UpdatedAt:       updated,
+			InnerSourceMetadata: &InnerSourceMetadata{
+				Title:         "Widget",
+				Motivation:    "Because everyone needs widgets.",
+				Contributions: []string{"Bugfixes", "Docs"},
+				Skills:        []string{"Go"},
+				Docs:          "https://acme.example/widget/docs",
+				Participation: []int{1, 2, 3},
+				Guidelines:    "https://github.com/acme/widget/blob/main/CONTRIBUTING.md",
+				Score:         123,
+			},
+		},
+		{
+			Name:            "gadget",
+			FullName:        "acme/gadget",
+			HTMLURL:         "https://github.com/acme/gadget",
+			Owner:           "acme",
+			ForksCount:      0,
+			StargazersCount: 0,
+			WatchersCount:   0,
+			OpenIssuesCount: 0,
+			CreatedAt:       created,
+			UpdatedAt:       updated,
+		},
+	}
+}
+
+func TestMarshalGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		golden string
+	}{
+		{name: "portal", format: FormatPortal, golden: "portal.json"},
+		{name: "csv", format: FormatCSV, golden: "repos.csv"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Marshal(tt.format, testRepos())
+			if err != nil {
+				t.Fatalf("Marshal(%s): %v", tt.format, err)
+			}
+
+			golden := filepath.Join("testdata", tt.golden)
+
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatalf("writing golden file: %v", err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("reading golden file: %v", err)
+			}
+
+			if string(got) != string(want) {
+				t.Errorf("Marshal(%s) output does not match %s\ngot:\n%s\nwant:\n%s", tt.format, golden, got, want)
+			}
+		})
+	}
+}
+
+func TestMarshalUnsupportedFormat(t *testing.T) {
+	if _, err := Marshal(FormatRaw, testRepos()); err == nil {
+		t.Fatal("Marshal(FormatRaw): expected error, got nil")
+	}
+}